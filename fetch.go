@@ -9,25 +9,45 @@ import (
 	"io/ioutil"
 	"net/http"
 	"strconv"
+	"strings"
+	"sync"
 	"syscall/js"
 )
 
 // Adapted for syscall/js from
 // https://github.com/gopherjs/gopherjs/blob/8dffc02ea1cb8398bb73f30424697c60fcf8d4c5/compiler/natives/src/net/http/fetch.go
 
+// Aborter is implemented by the *http.Response Body returned from
+// Transport.RoundTrip. It exposes the request's underlying
+// AbortController, so a caller that has a hold of the response body (but
+// not the original request context) can still cancel an in-flight read,
+// e.g. from a different goroutine than the one driving the request.
+// The empty body of the response synthesized for FollowRedirectsManual
+// does not implement it.
+type Aborter interface {
+	// Abort cancels the in-flight fetch() request and any pending body
+	// read, as if the request's context had been canceled.
+	Abort()
+}
+
 // streamReader implements an io.ReadCloser wrapper for ReadableStream of https://fetch.spec.whatwg.org/.
 type streamReader struct {
-	pending []byte
-	stream  js.Value
+	pending    []byte
+	stream     js.Value
+	ac         js.Value
+	bytesRead  int64
+	onProgress func(bytesRead int64)
 }
 
 func (r *streamReader) Read(p []byte) (n int, err error) {
 	if len(r.pending) == 0 {
 		var (
-			bCh   = make(chan []byte)
-			errCh = make(chan error)
+			bCh              = make(chan []byte)
+			errCh            = make(chan error)
+			success, failure js.Callback
 		)
-		success := js.NewCallback(func(args []js.Value) {
+		success = js.NewCallback(func(args []js.Value) {
+			defer func() { success.Close(); failure.Close() }()
 			result := args[0]
 			if result.Get("done").Bool() {
 				errCh <- io.EOF
@@ -37,12 +57,11 @@ func (r *streamReader) Read(p []byte) (n int, err error) {
 			js.ValueOf(value).Call("set", result.Get("value"))
 			bCh <- value
 		})
-		defer success.Close()
-		failure := js.NewCallback(func(args []js.Value) {
+		failure = js.NewCallback(func(args []js.Value) {
+			defer func() { success.Close(); failure.Close() }()
 			// Assumes it's a DOMException.
 			errCh <- errors.New(args[0].Get("message").String())
 		})
-		defer failure.Close()
 		r.stream.Call("read").Call("then", success, failure)
 		select {
 		case b := <-bCh:
@@ -53,6 +72,12 @@ func (r *streamReader) Read(p []byte) (n int, err error) {
 	}
 	n = copy(p, r.pending)
 	r.pending = r.pending[n:]
+	if n > 0 {
+		r.bytesRead += int64(n)
+		if r.onProgress != nil {
+			r.onProgress(r.bytesRead)
+		}
+	}
 	return n, nil
 }
 
@@ -64,38 +89,52 @@ func (r *streamReader) Close() error {
 	return nil
 }
 
+// Abort implements Aborter. It is a no-op if the host has no
+// AbortController (see Capabilities.AbortController).
+func (r *streamReader) Abort() {
+	if r.ac != js.Undefined {
+		r.ac.Call("abort")
+	}
+}
+
 // arrayReader implements an io.ReadCloser wrapper for arrayBuffer
 // https://developer.mozilla.org/en-US/docs/Web/API/Body/arrayBuffer.
 type arrayReader struct {
 	arrayPromise js.Value
+	ac           js.Value
 	pending      []byte
 	read         bool
+	onProgress   func(bytesRead int64)
 }
 
 func (r *arrayReader) Read(p []byte) (n int, err error) {
 	if !r.read {
 		r.read = true
 		var (
-			bCh   = make(chan []byte)
-			errCh = make(chan error)
+			bCh              = make(chan []byte)
+			errCh            = make(chan error)
+			success, failure js.Callback
 		)
-		success := js.NewCallback(func(args []js.Value) {
+		success = js.NewCallback(func(args []js.Value) {
+			defer func() { success.Close(); failure.Close() }()
 			// Wrap the input ArrayBuffer with a Uint8Array
 			uint8arrayWrapper := js.Global.Get("Uint8Array").New(args[0])
 			value := make([]byte, uint8arrayWrapper.Get("byteLength").Int())
 			js.ValueOf(value).Call("set", uint8arrayWrapper)
 			bCh <- value
 		})
-		defer success.Close()
-		failure := js.NewCallback(func(args []js.Value) {
+		failure = js.NewCallback(func(args []js.Value) {
+			defer func() { success.Close(); failure.Close() }()
 			// Assumes it's a DOMException.
 			errCh <- errors.New(args[0].Get("message").String())
 		})
-		defer failure.Close()
 		r.arrayPromise.Call("then", success, failure)
 		select {
 		case b := <-bCh:
 			r.pending = b
+			if r.onProgress != nil {
+				r.onProgress(int64(len(b)))
+			}
 		case err := <-errCh:
 			return 0, err
 		}
@@ -113,39 +152,439 @@ func (r *arrayReader) Close() error {
 	return nil
 }
 
+// Abort implements Aborter. It is a no-op if the host has no
+// AbortController (see Capabilities.AbortController).
+func (r *arrayReader) Abort() {
+	if r.ac != js.Undefined {
+		r.ac.Call("abort")
+	}
+}
+
 // Transport is a RoundTripper that is implemented using the WHATWG Fetch API.
 // It supports streaming response bodies.
-type Transport struct{}
+//
+// The Mode, Credentials, Redirect, Cache, Referrer, ReferrerPolicy and
+// Integrity fields map directly onto the corresponding fetch() init
+// options (https://developer.mozilla.org/en-US/docs/Web/API/fetch), and
+// are used for every request made through this Transport. They can be
+// overridden on a per-request basis by setting one of the magic header
+// keys below on the *http.Request before it is sent, mirroring the
+// pattern used by Go's own net/http/roundtrip_js.go. The magic headers
+// are stripped from the outgoing Headers object before fetch() is
+// called, so they never reach the network.
+type Transport struct {
+	// Mode corresponds to the request mode option, e.g. "cors",
+	// "no-cors" or "same-origin". Defaults to "cors" if unset.
+	Mode string
+	// Credentials corresponds to the request credentials option, e.g.
+	// "omit", "same-origin" or "include". Defaults to "same-origin" if
+	// unset, matching the previous hard-coded behavior.
+	Credentials string
+	// Redirect corresponds to the request redirect option, e.g.
+	// "follow", "error" or "manual". Defaults to "follow" if unset.
+	Redirect string
+	// Cache corresponds to the request cache option, e.g. "default",
+	// "no-store", "reload", "no-cache", "force-cache" or
+	// "only-if-cached".
+	Cache string
+	// Referrer corresponds to the request referrer option. It is
+	// passed through to fetch() unvalidated, since it may be an
+	// arbitrary URL or "about:client" or "".
+	Referrer string
+	// ReferrerPolicy corresponds to the request referrerPolicy option,
+	// e.g. "no-referrer" or "strict-origin-when-cross-origin".
+	ReferrerPolicy string
+	// Integrity corresponds to the request integrity option, a
+	// subresource integrity hash. It is passed through to fetch()
+	// unvalidated.
+	Integrity string
+	// ForceBufferedRequests disables streaming request bodies, falling
+	// back to reading the whole body into memory with ioutil.ReadAll
+	// before calling fetch(), which was this Transport's only behavior
+	// previously. Streaming is also disabled automatically when the
+	// runtime's ReadableStream does not support being used as a
+	// request body.
+	ForceBufferedRequests bool
+	// FollowRedirects controls how this Transport deals with redirect
+	// responses. It takes precedence over Redirect and the
+	// "js.fetch:redirect" header when set to FollowRedirectsManual or
+	// FollowRedirectsError. Defaults to FollowRedirectsBrowser.
+	FollowRedirects RedirectPolicy
+	// OnResponseProgress, if set, is called every time another chunk of
+	// the response body has been read, with the cumulative number of
+	// bytes read so far and the response's Content-Length (-1 if
+	// unknown). It is called from whatever goroutine is reading the
+	// response body.
+	OnResponseProgress func(req *http.Request, bytesRead, contentLength int64)
+	// FetchFunc, if set, is invoked instead of the ambient global fetch
+	// to perform the request. This allows using this Transport in
+	// environments where fetch is not a plain global, or where a
+	// specific implementation is required, e.g. a Service Worker's
+	// FetchEvent-scoped fetch, or a polyfill for a runtime (Deno,
+	// Cloudflare Workers) that doesn't expose one as expected.
+	FetchFunc js.Value
+	// Capabilities describes the features detected on the host's
+	// globals (Request, ReadableStream and AbortController); FetchFunc,
+	// when set, is not probed itself. It is populated once, by feature-probing, the
+	// first time this Transport is used, and is then consulted to pick
+	// the streaming/buffered request body path and whether requests
+	// can be aborted. Capabilities is exported for callers that want to
+	// inspect it; it should not be set directly.
+	Capabilities Capabilities
+
+	capsOnce sync.Once
+}
+
+// Capabilities describes the fetch-related features detected on a given
+// host (browser, Node.js/undici, Deno, Cloudflare Workers, Service
+// Workers, ...), since support for request streaming and cancellation
+// varies between them.
+type Capabilities struct {
+	// StreamingRequestBodies reports whether the host actually accepts
+	// a ReadableStream as a fetch() request body (with duplex: "half").
+	// This is more than just the presence of the ReadableStream
+	// constructor: some hosts (e.g. Safari/WebKit) have long supported
+	// ReadableStream without supporting it as a request body. When
+	// false, request bodies are always buffered in memory, regardless
+	// of Transport.ForceBufferedRequests.
+	StreamingRequestBodies bool
+	// AbortController reports whether the host provides a global
+	// AbortController, needed to cancel in-flight requests and to
+	// implement Aborter on the response body. When false, canceling a
+	// request's context stops waiting for its result locally, but the
+	// underlying fetch() is not actually aborted.
+	AbortController bool
+}
+
+// detectCapabilities feature-probes the host once and caches the result
+// on the Transport.
+func (t *Transport) detectCapabilities() {
+	t.capsOnce.Do(func() {
+		t.Capabilities = Capabilities{
+			StreamingRequestBodies: supportsStreamingRequestBodies(),
+			AbortController:        js.Global.Get("AbortController") != js.Undefined,
+		}
+	})
+}
+
+// supportsStreamingRequestBodiesScript is the standard feature-detection
+// idiom for whether a host's fetch() accepts a streamed request body: a
+// Request is constructed with a ReadableStream body and a "duplex"
+// accessor, and the host is only treated as supporting it if that
+// accessor was actually read while building the request (some hosts
+// advertise a ReadableStream constructor without ever honoring it as a
+// request body, and silently buffer or ignore it instead, which a mere
+// `typeof ReadableStream` check cannot tell apart).
+const supportsStreamingRequestBodiesScript = `
+if (typeof Request === 'undefined' || typeof ReadableStream === 'undefined') {
+	return false;
+}
+var duplexAccessed = false;
+try {
+	var req = new Request('https://example.com', {
+		method: 'POST',
+		body: new ReadableStream(),
+		get duplex() {
+			duplexAccessed = true;
+			return 'half';
+		},
+	});
+	// A host that does not treat the stream as a body converts it to a
+	// string instead, and then sets a text/plain Content-Type for it.
+	return duplexAccessed && !req.headers.has('Content-Type');
+} catch (e) {
+	return false;
+}
+`
+
+// supportsStreamingRequestBodies runs
+// supportsStreamingRequestBodiesScript against the current host.
+func supportsStreamingRequestBodies() bool {
+	probe := js.Global.Get("Function").New(supportsStreamingRequestBodiesScript)
+	return probe.Invoke().Bool()
+}
+
+// callFetch invokes the fetch implementation to use for a request:
+// Transport.FetchFunc if set, otherwise the ambient global fetch.
+//
+// The ambient global fetch is called with js.Global.Call rather than
+// js.Global.Get("fetch").Invoke, because browsers brand-check the
+// receiver fetch() is called on and throw a TypeError ("Illegal
+// invocation") when it is invoked detached from window/self; Call binds
+// this to js.Global, matching how a plain `fetch(...)` expression
+// behaves. A caller-supplied FetchFunc, on the other hand, is an
+// already-detached value with no implicit receiver to preserve, so it is
+// invoked directly.
+func (t *Transport) callFetch(url string, opt js.Value) (js.Value, bool) {
+	if t.FetchFunc != js.Undefined {
+		return t.FetchFunc.Invoke(url, opt), true
+	}
+	if js.Global.Get("fetch") == js.Undefined {
+		return js.Value{}, false
+	}
+	return js.Global.Call("fetch", url, opt), true
+}
+
+// RedirectPolicy controls whether a Transport lets the underlying fetch()
+// call follow redirects transparently, or hands them back to the caller
+// (e.g. so that http.Client can apply its own CheckRedirect policy).
+type RedirectPolicy string
+
+const (
+	// FollowRedirectsBrowser follows redirects the same way a browser
+	// would, transparently. This is the default, and matches this
+	// Transport's original behavior.
+	FollowRedirectsBrowser RedirectPolicy = "browser"
+	// FollowRedirectsManual sets the fetch() redirect option to
+	// "manual" so RoundTrip can observe that a redirect happened.
+	//
+	// Per the Fetch spec, the HTTP-redirect-fetch algorithm always
+	// produces an opaque-redirect filtered response under redirect:
+	// "manual" — type "opaqueredirect", status 0, an empty header
+	// list and a null body — unconditionally, with no same-origin
+	// carve-out, in every spec-compliant implementation including
+	// Node.js (undici). The real status code and Location header are
+	// therefore never recoverable from the fetch() response itself;
+	// RoundTrip can only synthesize a generic http.StatusFound
+	// response with no Location header, which tells the caller a
+	// redirect occurred but is not enough information for
+	// http.Client to follow it, so the chain stops at the first hop.
+	FollowRedirectsManual RedirectPolicy = "manual"
+	// FollowRedirectsError sets the fetch() redirect option to "error",
+	// causing RoundTrip to return an error as soon as a redirect
+	// response is encountered.
+	FollowRedirectsError RedirectPolicy = "error"
+)
+
+// Magic header keys that let a caller override a Transport's fetch
+// options on a per-request basis. These are stripped from the outgoing
+// Headers object before the request is sent.
+const (
+	headerFetchMode           = "js.fetch:mode"
+	headerFetchCredentials    = "js.fetch:credentials"
+	headerFetchRedirect       = "js.fetch:redirect"
+	headerFetchCache          = "js.fetch:cache"
+	headerFetchReferrer       = "js.fetch:referrer"
+	headerFetchReferrerPolicy = "js.fetch:referrer-policy"
+	headerFetchIntegrity      = "js.fetch:integrity"
+)
+
+// fetchOverrideHeaders lists the magic headers understood by RoundTrip, in
+// their canonical http.Header form, so they can be skipped when copying
+// req.Header into the JS Headers object.
+var fetchOverrideHeaders = map[string]bool{
+	http.CanonicalHeaderKey(headerFetchMode):           true,
+	http.CanonicalHeaderKey(headerFetchCredentials):    true,
+	http.CanonicalHeaderKey(headerFetchRedirect):       true,
+	http.CanonicalHeaderKey(headerFetchCache):          true,
+	http.CanonicalHeaderKey(headerFetchReferrer):       true,
+	http.CanonicalHeaderKey(headerFetchReferrerPolicy): true,
+	http.CanonicalHeaderKey(headerFetchIntegrity):      true,
+}
+
+var validFetchModes = map[string]bool{
+	"same-origin": true,
+	"no-cors":     true,
+	"cors":        true,
+}
+
+var validFetchCredentials = map[string]bool{
+	"omit":        true,
+	"same-origin": true,
+	"include":     true,
+}
+
+var validFetchRedirects = map[string]bool{
+	"follow": true,
+	"error":  true,
+	"manual": true,
+}
+
+var validFetchCaches = map[string]bool{
+	"default":        true,
+	"no-store":       true,
+	"reload":         true,
+	"no-cache":       true,
+	"force-cache":    true,
+	"only-if-cached": true,
+}
+
+var validFetchReferrerPolicies = map[string]bool{
+	"":                                true,
+	"no-referrer":                     true,
+	"no-referrer-when-downgrade":      true,
+	"origin":                          true,
+	"origin-when-cross-origin":        true,
+	"same-origin":                     true,
+	"strict-origin":                   true,
+	"strict-origin-when-cross-origin": true,
+	"unsafe-url":                      true,
+}
+
+// newRequestBodyStream wraps req.Body in a pull-based JS ReadableStream so
+// it can be streamed to fetch() without buffering it in memory first.
+//
+// Because fetch() is called with duplex: "half", the browser may start
+// delivering the response before the stream has been fully pulled, so the
+// pull and cancel callbacks cannot be released as soon as RoundTrip gets a
+// response; instead they release themselves once the stream reaches a
+// terminal state (closed, errored or canceled).
+func newRequestBodyStream(req *http.Request) js.Value {
+	source := js.Global.Get("Object").New()
+	var (
+		pull, cancel js.Callback
+		releaseOnce  sync.Once
+	)
+	release := func() {
+		releaseOnce.Do(func() {
+			pull.Close()
+			cancel.Close()
+		})
+	}
+	pull = js.NewCallback(func(args []js.Value) {
+		controller := args[0]
+		buf := make([]byte, 16*1024)
+		n, err := req.Body.Read(buf)
+		if n > 0 {
+			controller.Call("enqueue", js.ValueOf(buf[:n]))
+		}
+		switch err {
+		case nil:
+		case io.EOF:
+			controller.Call("close")
+			_ = req.Body.Close()
+			release()
+		default:
+			controller.Call("error", err.Error())
+			_ = req.Body.Close()
+			release()
+		}
+	})
+	source.Set("pull", pull)
+	cancel = js.NewCallback(func(args []js.Value) {
+		_ = req.Body.Close()
+		release()
+	})
+	source.Set("cancel", cancel)
+	return js.Global.Get("ReadableStream").New(source)
+}
+
+// fetchOption resolves the effective value for a fetch option given the
+// Transport default and a per-request header override, validating the
+// result against the allowed set.
+func fetchOption(name, def string, header http.Header, headerKey string, allowed map[string]bool) (string, error) {
+	value := def
+	if v := header.Get(headerKey); v != "" {
+		value = v
+	}
+	if value != "" && !allowed[value] {
+		return "", fmt.Errorf("net/http: invalid %s %q", name, value)
+	}
+	return value, nil
+}
 
 // RoundTrip performs a full round trip of a request.
 func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.detectCapabilities()
+
+	mode, err := fetchOption("fetch mode", t.Mode, req.Header, headerFetchMode, validFetchModes)
+	if err != nil {
+		return nil, err
+	}
+	credentials, err := fetchOption("fetch credentials", t.Credentials, req.Header, headerFetchCredentials, validFetchCredentials)
+	if err != nil {
+		return nil, err
+	}
+	if credentials == "" {
+		credentials = "same-origin"
+	}
+	redirect, err := fetchOption("fetch redirect", t.Redirect, req.Header, headerFetchRedirect, validFetchRedirects)
+	if err != nil {
+		return nil, err
+	}
+	switch t.FollowRedirects {
+	case FollowRedirectsManual:
+		redirect = "manual"
+	case FollowRedirectsError:
+		redirect = "error"
+	}
+	cache, err := fetchOption("fetch cache", t.Cache, req.Header, headerFetchCache, validFetchCaches)
+	if err != nil {
+		return nil, err
+	}
+	referrerPolicy, err := fetchOption("fetch referrer policy", t.ReferrerPolicy, req.Header, headerFetchReferrerPolicy, validFetchReferrerPolicies)
+	if err != nil {
+		return nil, err
+	}
+	referrer := t.Referrer
+	if v := req.Header.Get(headerFetchReferrer); v != "" {
+		referrer = v
+	}
+	integrity := t.Integrity
+	if v := req.Header.Get(headerFetchIntegrity); v != "" {
+		integrity = v
+	}
+
 	headers := js.Global.Get("Headers").New()
 	for key, values := range req.Header {
+		if fetchOverrideHeaders[http.CanonicalHeaderKey(key)] {
+			continue
+		}
 		for _, value := range values {
 			headers.Call("append", key, value)
 		}
 	}
 
-	ac := js.Global.Get("AbortController").New()
+	var ac js.Value
+	if t.Capabilities.AbortController {
+		ac = js.Global.Get("AbortController").New()
+	}
 
 	opt := js.Global.Get("Object").New()
 	opt.Set("headers", headers)
 	opt.Set("method", req.Method)
-	opt.Set("credentials", "same-origin")
-	opt.Set("signal", ac.Get("signal"))
+	opt.Set("credentials", credentials)
+	if t.Capabilities.AbortController {
+		opt.Set("signal", ac.Get("signal"))
+	}
+	if mode != "" {
+		opt.Set("mode", mode)
+	}
+	if redirect != "" {
+		opt.Set("redirect", redirect)
+	}
+	if cache != "" {
+		opt.Set("cache", cache)
+	}
+	if referrer != "" {
+		opt.Set("referrer", referrer)
+	}
+	if referrerPolicy != "" {
+		opt.Set("referrerPolicy", referrerPolicy)
+	}
+	if integrity != "" {
+		opt.Set("integrity", integrity)
+	}
 
 	if req.Body != nil {
-		body, err := ioutil.ReadAll(req.Body)
-		if err != nil {
-			_ = req.Body.Close() // RoundTrip must always close the body, including on errors.
-			return nil, err
+		if !t.ForceBufferedRequests && t.Capabilities.StreamingRequestBodies {
+			opt.Set("body", newRequestBodyStream(req))
+			// Browsers require duplex: "half" on the fetch init whenever
+			// the request body is a stream.
+			opt.Set("duplex", "half")
+		} else {
+			body, err := ioutil.ReadAll(req.Body)
+			if err != nil {
+				_ = req.Body.Close() // RoundTrip must always close the body, including on errors.
+				return nil, err
+			}
+			_ = req.Body.Close()
+			opt.Set("body", body)
 		}
-		_ = req.Body.Close()
-		opt.Set("body", body)
 	}
-	respPromise := js.Global.Call("fetch", req.URL.String(), opt)
-	if respPromise == js.Undefined {
-		return nil, errors.New("your browser does not support the Fetch API, please upgrade")
+	respPromise, ok := t.callFetch(req.URL.String(), opt)
+	if !ok {
+		return nil, errors.New("net/http: no fetch implementation available, please upgrade your runtime or set Transport.FetchFunc")
 	}
 
 	var (
@@ -154,6 +593,31 @@ func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
 	)
 	success := js.NewCallback(func(args []js.Value) {
 		result := args[0]
+
+		if result.Get("type").String() == "opaqueredirect" {
+			// redirect: "manual" was requested and fetch() stopped at a
+			// redirect without exposing it. Per the Fetch spec this
+			// opaque-redirect response unconditionally has status 0 and
+			// an empty header list, in every runtime, so there is no
+			// real status code or Location to recover here. Synthesize
+			// a generic http.StatusFound response with no Location so
+			// the caller at least learns a redirect happened; without a
+			// Location, http.Client cannot follow it and stops here.
+			status := http.StatusFound
+			header := http.Header{}
+			select {
+			case respCh <- &http.Response{
+				Status:     strconv.Itoa(status) + " " + http.StatusText(status),
+				StatusCode: status,
+				Header:     header,
+				Body:       ioutil.NopCloser(strings.NewReader("")),
+				Request:    req,
+			}:
+			case <-req.Context().Done():
+			}
+			return
+		}
+
 		header := http.Header{}
 		writeHeaders := js.NewCallback(func(args []js.Value) {
 			key, value := args[0].String(), args[1].String()
@@ -168,14 +632,21 @@ func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
 			contentLength = cl
 		}
 
+		var onProgress func(bytesRead int64)
+		if t.OnResponseProgress != nil {
+			onProgress = func(bytesRead int64) {
+				t.OnResponseProgress(req, bytesRead, contentLength)
+			}
+		}
+
 		b := result.Get("body")
 		var body io.ReadCloser
 		if b != js.Undefined {
-			body = &streamReader{stream: b.Call("getReader")}
+			body = &streamReader{stream: b.Call("getReader"), ac: ac, onProgress: onProgress}
 		} else {
 			// Fall back to using the arrayBuffer
 			// https://developer.mozilla.org/en-US/docs/Web/API/Body/arrayBuffer
-			body = &arrayReader{arrayPromise: result.Call("arrayBuffer")}
+			body = &arrayReader{arrayPromise: result.Call("arrayBuffer"), ac: ac, onProgress: onProgress}
 		}
 
 		select {
@@ -201,8 +672,10 @@ func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
 	respPromise.Call("then", success, failure)
 	select {
 	case <-req.Context().Done():
-		// Abort the Fetch request
-		ac.Call("abort")
+		if t.Capabilities.AbortController {
+			// Abort the Fetch request
+			ac.Call("abort")
+		}
 		return nil, errors.New("net/http: request canceled")
 	case resp := <-respCh:
 		return resp, nil